@@ -704,6 +704,10 @@ func CopyModel(src, dest string) error {
 	}
 
 	destModelPath := ParseModelPath(dest)
+	if err := destModelPath.Validate(); err != nil {
+		return err
+	}
+
 	destPath, err := destModelPath.GetManifestPath()
 	if err != nil {
 		return err
@@ -804,9 +808,20 @@ func PruneLayers() error {
 	for _, blob := range blobs {
 		name := blob.Name()
 		name = strings.ReplaceAll(name, "-", ":")
-		if strings.HasPrefix(name, "sha256:") {
-			deleteMap[name] = struct{}{}
+		if !strings.HasPrefix(name, "sha256:") {
+			continue
 		}
+
+		// partial downloads and temp files aren't valid digests so they can't
+		// be resolved through GetBlobsPath; remove them directly
+		if _, err := GetBlobsPath(name); errors.Is(err, ErrInvalidDigestFormat) {
+			if err := os.Remove(filepath.Join(p, blob.Name())); err != nil {
+				slog.Info(fmt.Sprintf("couldn't remove file '%s': %v", blob.Name(), err))
+			}
+			continue
+		}
+
+		deleteMap[name] = struct{}{}
 	}
 
 	slog.Info(fmt.Sprintf("total blobs: %d", len(deleteMap)))
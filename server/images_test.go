@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ollama/ollama/types/model"
+)
+
+func TestPruneLayers(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OLLAMA_MODELS", dir)
+
+	p, err := GetBlobsPath("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := func(c string) string {
+		return "sha256:" + strings.Repeat(c, 64)
+	}
+
+	// manifests are written directly so that the over-long tag, which Validate
+	// rejects for new names, is treated like one that already exists on disk
+	manifests := map[string]ManifestV2{
+		filepath.Join(DefaultRegistry, DefaultNamespace, "repo", "latest"): {
+			Config: &Layer{Digest: digest("a")},
+			Layers: []*Layer{{Digest: digest("b")}},
+		},
+		filepath.Join(DefaultRegistry, DefaultNamespace, "repo", strings.Repeat("t", model.MaxNamePartLen+1)): {
+			Config: &Layer{Digest: digest("a")},
+			Layers: []*Layer{{Digest: digest("c")}},
+		},
+	}
+
+	for name, m := range manifests {
+		fp := filepath.Join(dir, "manifests", name)
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		bts, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(fp, bts, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kept := []string{
+		"sha256-" + strings.Repeat("a", 64),
+		"sha256-" + strings.Repeat("b", 64),
+		"sha256-" + strings.Repeat("c", 64),
+	}
+
+	removed := []string{
+		"sha256-" + strings.Repeat("d", 64),
+		"sha256-" + strings.Repeat("b", 64) + "-partial",
+		"sha256-" + strings.Repeat("b", 64) + "-partial-0",
+		"sha256-123456789-partial",
+	}
+
+	for _, name := range append(kept, removed...) {
+		if err := os.WriteFile(filepath.Join(p, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := PruneLayers(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range kept {
+		if _, err := os.Stat(filepath.Join(p, name)); err != nil {
+			t.Errorf("expected %q to be kept, got %v", name, err)
+		}
+	}
+
+	for _, name := range removed {
+		if _, err := os.Stat(filepath.Join(p, name)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected %q to be removed, got %v", name, err)
+		}
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+
+	"github.com/ollama/ollama/types/model"
 )
 
 type ModelPath struct {
@@ -25,9 +28,10 @@ const (
 )
 
 var (
-	ErrInvalidImageFormat = errors.New("invalid image format")
-	ErrInvalidProtocol    = errors.New("invalid protocol scheme")
-	ErrInsecureProtocol   = errors.New("insecure protocol http")
+	ErrInvalidImageFormat  = errors.New("invalid image format")
+	ErrInvalidProtocol     = errors.New("invalid protocol scheme")
+	ErrInsecureProtocol    = errors.New("insecure protocol http")
+	ErrInvalidDigestFormat = errors.New("invalid digest format")
 )
 
 func ParseModelPath(name string) ModelPath {
@@ -78,9 +82,46 @@ func (mp ModelPath) Validate() error {
 		return fmt.Errorf("%w: ':' (colon) is not allowed in tag names", errModelPathInvalid)
 	}
 
+	if err := mp.validatePath(); err != nil {
+		return err
+	}
+
+	// names on disk aren't limited, so these are only checked for new names
+	for _, part := range []string{mp.Registry, mp.Namespace, mp.Repository, mp.Tag} {
+		if len(part) > model.MaxNamePartLen {
+			return fmt.Errorf("%w: %q is longer than %d characters", errModelPathInvalid, part, model.MaxNamePartLen)
+		}
+
+		if strings.Contains(part, "\\") {
+			return fmt.Errorf("%w: '\\' (backslash) is not allowed in %q", errModelPathInvalid, part)
+		}
+	}
+
 	return nil
 }
 
+// validatePath checks that each part of mp can be used as a single element of
+// a manifest path without escaping the manifests directory. Unlike Validate it
+// accepts any name that may already exist on disk.
+func (mp ModelPath) validatePath() error {
+	for _, part := range []string{mp.Registry, mp.Namespace, mp.Repository, mp.Tag} {
+		if !isValidPathPart(part) {
+			return fmt.Errorf("%w: %q is not a valid path element", errModelPathInvalid, part)
+		}
+	}
+
+	return nil
+}
+
+// isValidPathPart reports whether s can be used as a single element of a
+// manifest path without escaping the manifests directory.
+func isValidPathPart(s string) bool {
+	if s == "" || s == "." || s == ".." {
+		return false
+	}
+	return !strings.ContainsAny(s, "/\x00") && !strings.ContainsRune(s, filepath.Separator)
+}
+
 func (mp ModelPath) GetNamespaceRepository() string {
 	return fmt.Sprintf("%s/%s", mp.Namespace, mp.Repository)
 }
@@ -114,6 +155,10 @@ func modelsDir() (string, error) {
 
 // GetManifestPath returns the path to the manifest file for the given model path, it is up to the caller to create the directory if it does not exist.
 func (mp ModelPath) GetManifestPath() (string, error) {
+	if err := mp.validatePath(); err != nil {
+		return "", err
+	}
+
 	dir, err := modelsDir()
 	if err != nil {
 		return "", err
@@ -143,7 +188,16 @@ func GetManifestPath() (string, error) {
 	return path, nil
 }
 
+var blobDigestRe = regexp.MustCompile("^sha256[:-][0-9a-f]{64}$")
+
+// GetBlobsPath returns the path to the blob with the given digest, or the blobs
+// directory itself if digest is empty. Only sha256 digests are accepted so that
+// the digest cannot be used to reach outside of the blobs directory.
 func GetBlobsPath(digest string) (string, error) {
+	if digest != "" && !blobDigestRe.MatchString(digest) {
+		return "", ErrInvalidDigestFormat
+	}
+
 	dir, err := modelsDir()
 	if err != nil {
 		return "", err
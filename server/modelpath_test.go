@@ -1,6 +1,13 @@
 package server
 
-import "testing"
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ollama/ollama/types/model"
+)
 
 func TestParseModelPath(t *testing.T) {
 	tests := []struct {
@@ -86,3 +93,178 @@ func TestParseModelPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGetManifestPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OLLAMA_MODELS", dir)
+
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{"registry.ollama.ai/library/llama2:7b", filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama2", "7b"), nil},
+		{"localhost:5000/ns/repo:tag", filepath.Join(dir, "manifests", "localhost:5000", "ns", "repo", "tag"), nil},
+		{"ns/repo", filepath.Join(dir, "manifests", DefaultRegistry, "ns", "repo", DefaultTag), nil},
+		{"repo:" + strings.Repeat("a", model.MaxNamePartLen), filepath.Join(dir, "manifests", DefaultRegistry, DefaultNamespace, "repo", strings.Repeat("a", model.MaxNamePartLen)), nil},
+		{"../ns/repo:tag", "", errModelPathInvalid},
+		{"../../repo", "", errModelPathInvalid},
+		{"repo:..", "", errModelPathInvalid},
+		{"ns//repo", "", errModelPathInvalid},
+		{"repo:tag\x00", "", errModelPathInvalid},
+		// names that may already be on disk are only checked for traversal
+		{"repo:" + strings.Repeat("a", model.MaxNamePartLen+1), filepath.Join(dir, "manifests", DefaultRegistry, DefaultNamespace, "repo", strings.Repeat("a", model.MaxNamePartLen+1)), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseModelPath(tt.name).GetManifestPath()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelPathValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"registry.ollama.ai/library/llama2:7b", nil},
+		{"localhost:5000/ns/repo:tag", nil},
+		{"repo:" + strings.Repeat("a", model.MaxNamePartLen), nil},
+		{"", errModelPathInvalid},
+		{"repo:a:b", errModelPathInvalid},
+		{"../ns/repo:tag", errModelPathInvalid},
+		{"repo:..", errModelPathInvalid},
+		{"ns//repo", errModelPathInvalid},
+		{`..\..\repo`, errModelPathInvalid},
+		{`ns/re\po`, errModelPathInvalid},
+		{"repo:" + strings.Repeat("a", model.MaxNamePartLen+1), errModelPathInvalid},
+		{strings.Repeat("a", model.MaxNamePartLen+1) + "/repo", errModelPathInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ParseModelPath(tt.name).Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGetBlobsPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OLLAMA_MODELS", dir)
+
+	hex := strings.Repeat("a", 64)
+	tests := []struct {
+		digest string
+		want   string
+		err    error
+	}{
+		{"", filepath.Join(dir, "blobs"), nil},
+		{"sha256:" + hex, filepath.Join(dir, "blobs", "sha256-"+hex), nil},
+		{"sha256-" + hex, filepath.Join(dir, "blobs", "sha256-"+hex), nil},
+		{"sha256-1234", "", ErrInvalidDigestFormat},
+		{"sha256:" + strings.ToUpper(hex), "", ErrInvalidDigestFormat},
+		{"sha256-" + strings.Repeat("A", 64), "", ErrInvalidDigestFormat},
+		{"sha512-" + hex + hex, "", ErrInvalidDigestFormat},
+		{"../sha256-" + hex, "", ErrInvalidDigestFormat},
+		{"sha256-" + hex + "/../../x", "", ErrInvalidDigestFormat},
+		{"sha256-" + hex + "-partial-0", "", ErrInvalidDigestFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.digest, func(t *testing.T) {
+			got, err := GetBlobsPath(tt.digest)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseModelPath(f *testing.F) {
+	f.Add("registry.ollama.ai/library/llama2:7b")
+	f.Add("https://example.com/ns/repo:tag")
+	f.Add("../../repo")
+	f.Add("ns/../repo")
+	f.Add("../ns/repo:tag")
+	f.Add("repo:..")
+	f.Add("ns//repo")
+	f.Add("/repo")
+	f.Add(`..\..\repo`)
+	f.Add("https://../../repo:tag")
+	f.Add("repo:tag\x00")
+	f.Add("ünïcödé/répo:täg")
+	f.Add(strings.Repeat("a", 1024) + ":" + strings.Repeat("b", 1024))
+	f.Add("repo:tag; rm -rf /")
+
+	dir := f.TempDir()
+	f.Setenv("OLLAMA_MODELS", dir)
+	manifests := filepath.Join(dir, "manifests") + string(filepath.Separator)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		mp := ParseModelPath(s)
+		p, err := mp.GetManifestPath()
+		if err != nil {
+			if mp.Validate() == nil {
+				t.Fatalf("GetManifestPath failed for valid model path %q: %v", s, err)
+			}
+			return
+		}
+
+		if !strings.HasPrefix(p, manifests) {
+			t.Fatalf("manifest path %q for %q escapes %q", p, s, manifests)
+		}
+
+		if mp.Validate() != nil {
+			return
+		}
+
+		for _, part := range []string{mp.Registry, mp.Namespace, mp.Repository, mp.Tag} {
+			if len(part) > model.MaxNamePartLen {
+				t.Fatalf("Validate accepted path element too long for %q: %q", s, part)
+			}
+		}
+	})
+}
+
+func FuzzGetBlobsPath(f *testing.F) {
+	f.Add("sha256-" + strings.Repeat("a", 64))
+	f.Add("sha256:" + strings.Repeat("0", 64))
+	f.Add("sha256-1234")
+	f.Add("../sha256-" + strings.Repeat("a", 64))
+	f.Add("sha256-" + strings.Repeat("a", 64) + "/../../x")
+	f.Add("sha512-" + strings.Repeat("a", 128))
+	f.Add("..")
+
+	dir := f.TempDir()
+	f.Setenv("OLLAMA_MODELS", dir)
+	blobs := filepath.Join(dir, "blobs") + string(filepath.Separator)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if s == "" {
+			t.Skip("empty digest returns the blobs directory")
+		}
+
+		p, err := GetBlobsPath(s)
+		if err != nil {
+			return
+		}
+
+		if !strings.HasPrefix(p, blobs) || filepath.Dir(p) != filepath.Clean(blobs) {
+			t.Fatalf("blob path %q for %q escapes %q", p, s, blobs)
+		}
+	})
+}